@@ -0,0 +1,175 @@
+package bmrot
+
+import (
+	"bufio"
+	"image/color"
+	"image/draw"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// BuildFromBDF bakes a BMFont atlas from a BDF (Glyph Bitmap Distribution
+// Format) font. Unlike BuildFromSFNT, glyphs are copied verbatim from the
+// file's own bitmaps rather than rasterized, so opts.PixelSize is ignored;
+// the baked glyph sizes are whatever the BDF file defines.
+func BuildFromBDF(r io.Reader, opts BuildOptions) (*BitmapFont, error) {
+	font, err := parseBDF(r)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[rune]bool)
+	for _, r := range opts.runes() {
+		wanted[r] = true
+	}
+
+	glyphs := make([]glyphBitmap, 0, len(font.glyphs))
+	for _, g := range font.glyphs {
+		if !wanted[g.r] {
+			continue
+		}
+		g := g
+		glyphs = append(glyphs, glyphBitmap{
+			r:        g.r,
+			width:    g.width,
+			height:   g.height,
+			xoffset:  g.xoff,
+			yoffset:  font.ascent - (g.yoff + g.height),
+			xadvance: g.dwidth,
+			draw: func(dst draw.Image, x, y int) {
+				for row, bits := range g.bitmap {
+					for col, set := range bits {
+						if set {
+							dst.Set(x+col, y+row, color.White)
+						}
+					}
+				}
+			},
+		})
+	}
+
+	bf, dropped := buildAtlas(glyphs, font.ascent+font.descent, font.ascent, opts)
+	if len(dropped) > 0 {
+		return nil, errDroppedGlyphs(dropped)
+	}
+	return bf, nil
+}
+
+// bdfGlyph is one STARTCHAR..ENDCHAR block of a BDF file.
+type bdfGlyph struct {
+	r             rune
+	width, height int
+	xoff, yoff    int
+	dwidth        int
+	bitmap        [][]bool
+}
+
+type bdfFont struct {
+	ascent, descent int
+	glyphs          []bdfGlyph
+}
+
+// parseBDF parses a BDF font, honoring FONT_ASCENT/FONT_DESCENT (falling
+// back to FONTBOUNDINGBOX if they're absent) so the caller can compute
+// Common.Base and Common.LineHeight correctly.
+func parseBDF(r io.Reader) (*bdfFont, error) {
+	scanner := bufio.NewScanner(r)
+	f := &bdfFont{}
+	var boundingHeight, boundingYOff int
+	haveAscent, haveDescent := false, false
+
+	var cur *bdfGlyph
+	inBitmap := false
+	bitmapRow := 0
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if inBitmap {
+			if line == "ENDCHAR" {
+				if cur != nil && cur.r >= 0 {
+					f.glyphs = append(f.glyphs, *cur)
+				}
+				cur = nil
+				inBitmap = false
+				continue
+			}
+			if cur != nil && bitmapRow < cur.height {
+				cur.bitmap[bitmapRow] = parseBDFHexRow(line, cur.width)
+			}
+			bitmapRow++
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "FONT_ASCENT":
+			f.ascent, _ = strconv.Atoi(fields[1])
+			haveAscent = true
+		case "FONT_DESCENT":
+			f.descent, _ = strconv.Atoi(fields[1])
+			haveDescent = true
+		case "FONTBOUNDINGBOX":
+			if len(fields) >= 5 {
+				boundingHeight, _ = strconv.Atoi(fields[2])
+				boundingYOff, _ = strconv.Atoi(fields[4])
+			}
+		case "STARTCHAR":
+			cur = &bdfGlyph{r: -1}
+		case "ENCODING":
+			if cur != nil && len(fields) >= 2 {
+				code, _ := strconv.Atoi(fields[1])
+				cur.r = rune(code)
+			}
+		case "DWIDTH":
+			if cur != nil && len(fields) >= 2 {
+				cur.dwidth, _ = strconv.Atoi(fields[1])
+			}
+		case "BBX":
+			if cur != nil && len(fields) >= 5 {
+				cur.width, _ = strconv.Atoi(fields[1])
+				cur.height, _ = strconv.Atoi(fields[2])
+				cur.xoff, _ = strconv.Atoi(fields[3])
+				cur.yoff, _ = strconv.Atoi(fields[4])
+			}
+		case "BITMAP":
+			if cur == nil {
+				cur = &bdfGlyph{r: -1}
+			}
+			cur.bitmap = make([][]bool, cur.height)
+			inBitmap = true
+			bitmapRow = 0
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !haveAscent || !haveDescent {
+		f.ascent = boundingHeight + boundingYOff
+		f.descent = -boundingYOff
+	}
+	return f, nil
+}
+
+// parseBDFHexRow decodes one BITMAP hex line into width booleans, most
+// significant bit first, as BDF packs each row's bits into whole bytes.
+func parseBDFHexRow(hex string, width int) []bool {
+	bits := make([]bool, width)
+	for i := 0; i < width; i++ {
+		byteIdx := i / 8
+		if byteIdx*2+2 > len(hex) {
+			break
+		}
+		b, err := strconv.ParseUint(hex[byteIdx*2:byteIdx*2+2], 16, 8)
+		if err != nil {
+			continue
+		}
+		bitPos := 7 - uint(i%8)
+		bits[i] = b&(1<<bitPos) != 0
+	}
+	return bits
+}
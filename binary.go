@@ -0,0 +1,339 @@
+package bmrot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Block types for the BMFont binary descriptor format. blockVKerning is not
+// part of the standard format; it is this package's own extension for
+// carrying Descriptor.VKerning (see Descriptor.Rotate), written after the
+// standard blocks so readers that don't know it can stop at blockKerning.
+const (
+	blockInfo     = 1
+	blockCommon   = 2
+	blockPages    = 3
+	blockChars    = 4
+	blockKerning  = 5
+	blockVKerning = 6
+)
+
+// Sizes, in bytes, of the fixed-width binary records. infoMinSizeBinary is
+// a minimum, not an exact size: it covers only the fixed fields, since the
+// face name that follows is a variable-length, nul-terminated string.
+const (
+	infoMinSizeBinary = 14
+	commonSizeBinary  = 15
+	charSizeBinary    = 20
+	kerningSizeBinary = 10
+)
+
+// maxBlockSize bounds how large a single block's declared size may be, so
+// a corrupted or hostile length prefix can't force an enormous allocation
+// before io.ReadFull gets a chance to fail.
+const maxBlockSize = 64 << 20 // 64 MiB
+
+// parseBinaryDescriptor parses a BMFont descriptor in the binary format:
+// the magic "BMF" followed by a version byte, then a sequence of
+// type-prefixed, length-prefixed blocks (info, common, pages, chars,
+// kerning pairs). name is only used in error messages.
+func parseBinaryDescriptor(name string, r io.Reader) (*Descriptor, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if header != binaryMagic {
+		return nil, fmt.Errorf("%s: not a BMFont binary descriptor", name)
+	}
+
+	d := &Descriptor{
+		Pages:   make(map[int]Page),
+		Chars:   make(map[rune]Char),
+		Kerning: make(map[CharPair]Kerning),
+	}
+	var pageNames []string
+	for {
+		var blockType byte
+		if err := binary.Read(r, binary.LittleEndian, &blockType); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		var size int32
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if size < 0 || size > maxBlockSize {
+			return nil, fmt.Errorf("%s: block size %d out of range", name, size)
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		switch blockType {
+		case blockInfo:
+			if len(data) < infoMinSizeBinary {
+				return nil, fmt.Errorf("%s: info block too short (%d bytes, want at least %d)", name, len(data), infoMinSizeBinary)
+			}
+			d.Info = parseInfoBinary(data)
+		case blockCommon:
+			if len(data) < commonSizeBinary {
+				return nil, fmt.Errorf("%s: common block too short (%d bytes, want %d)", name, len(data), commonSizeBinary)
+			}
+			d.Common = parseCommonBinary(data)
+		case blockPages:
+			pageNames = splitNulTerminated(data)
+		case blockChars:
+			for i := 0; i+charSizeBinary <= len(data); i += charSizeBinary {
+				c := parseCharBinary(data[i : i+charSizeBinary])
+				d.Chars[c.ID] = c
+			}
+		case blockKerning:
+			for i := 0; i+kerningSizeBinary <= len(data); i += kerningSizeBinary {
+				pair, k := parseKerningBinary(data[i : i+kerningSizeBinary])
+				d.Kerning[pair] = k
+			}
+		case blockVKerning:
+			if d.VKerning == nil {
+				d.VKerning = make(map[CharPair]Kerning)
+			}
+			for i := 0; i+kerningSizeBinary <= len(data); i += kerningSizeBinary {
+				pair, k := parseKerningBinary(data[i : i+kerningSizeBinary])
+				d.VKerning[pair] = k
+			}
+		default:
+			return nil, fmt.Errorf("%s: unknown block type %d", name, blockType)
+		}
+	}
+	for id, file := range pageNames {
+		d.Pages[id] = Page{ID: id, File: file}
+	}
+	return d, nil
+}
+
+func parseInfoBinary(b []byte) Info {
+	fontSize := int16(binary.LittleEndian.Uint16(b[0:2]))
+	bitField := b[2]
+	charSet := b[3]
+	stretchH := binary.LittleEndian.Uint16(b[4:6])
+	aa := b[6]
+	name := nulTerminatedString(b[14:])
+	charset := ""
+	if charSet != 0 {
+		charset = string(rune(charSet))
+	}
+	return Info{
+		Face:     name,
+		Size:     int(fontSize),
+		Bold:     bitField&0x08 != 0,
+		Italic:   bitField&0x04 != 0,
+		Charset:  charset,
+		Unicode:  bitField&0x02 != 0,
+		StretchH: int(stretchH),
+		Smooth:   bitField&0x01 != 0,
+		AA:       int(aa),
+		Padding:  Padding{Up: int(b[7]), Right: int(b[8]), Down: int(b[9]), Left: int(b[10])},
+		Spacing:  Spacing{Horizontal: int(b[11]), Vertical: int(b[12])},
+		Outline:  int(b[13]),
+	}
+}
+
+func parseCommonBinary(b []byte) Common {
+	bitField := b[10]
+	return Common{
+		LineHeight:   int(binary.LittleEndian.Uint16(b[0:2])),
+		Base:         int(binary.LittleEndian.Uint16(b[2:4])),
+		ScaleW:       int(binary.LittleEndian.Uint16(b[4:6])),
+		ScaleH:       int(binary.LittleEndian.Uint16(b[6:8])),
+		Packed:       bitField&0x01 != 0,
+		AlphaChannel: ChannelInfo(b[11]),
+		RedChannel:   ChannelInfo(b[12]),
+		GreenChannel: ChannelInfo(b[13]),
+		BlueChannel:  ChannelInfo(b[14]),
+	}
+}
+
+func parseCharBinary(b []byte) Char {
+	return Char{
+		ID:       rune(binary.LittleEndian.Uint32(b[0:4])),
+		X:        int(binary.LittleEndian.Uint16(b[4:6])),
+		Y:        int(binary.LittleEndian.Uint16(b[6:8])),
+		Width:    int(binary.LittleEndian.Uint16(b[8:10])),
+		Height:   int(binary.LittleEndian.Uint16(b[10:12])),
+		XOffset:  int(int16(binary.LittleEndian.Uint16(b[12:14]))),
+		YOffset:  int(int16(binary.LittleEndian.Uint16(b[14:16]))),
+		XAdvance: int(int16(binary.LittleEndian.Uint16(b[16:18]))),
+		Page:     int(b[18]),
+		Channel:  Channel(b[19]),
+	}
+}
+
+func parseKerningBinary(b []byte) (CharPair, Kerning) {
+	first := rune(binary.LittleEndian.Uint32(b[0:4]))
+	second := rune(binary.LittleEndian.Uint32(b[4:8]))
+	amount := int(int16(binary.LittleEndian.Uint16(b[8:10])))
+	return CharPair{First: first, Second: second}, Kerning{Amount: amount}
+}
+
+func nulTerminatedString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		return string(b[:i])
+	}
+	return string(b)
+}
+
+// splitNulTerminated splits b into the strings terminated by its 0x00
+// bytes, as used for the page names block.
+func splitNulTerminated(b []byte) []string {
+	var names []string
+	start := 0
+	for i, c := range b {
+		if c == 0 {
+			names = append(names, string(b[start:i]))
+			start = i + 1
+		}
+	}
+	return names
+}
+
+// writeBinary encodes d in BMFont's binary format (see
+// parseBinaryDescriptor).
+func (d *Descriptor) writeBinary(w io.Writer) error {
+	if _, err := w.Write(binaryMagic[:]); err != nil {
+		return err
+	}
+	if err := writeBlock(w, blockInfo, encodeInfoBinary(&d.Info)); err != nil {
+		return err
+	}
+	if err := writeBlock(w, blockCommon, encodeCommonBinary(&d.Common, len(d.Pages))); err != nil {
+		return err
+	}
+	if err := writeBlock(w, blockPages, encodePagesBinary(d.Pages)); err != nil {
+		return err
+	}
+	if err := writeBlock(w, blockChars, encodeCharsBinary(d.Chars)); err != nil {
+		return err
+	}
+	if len(d.Kerning) > 0 {
+		if err := writeBlock(w, blockKerning, encodeKerningBinary(d.Kerning)); err != nil {
+			return err
+		}
+	}
+	if len(d.VKerning) > 0 {
+		if err := writeBlock(w, blockVKerning, encodeKerningBinary(d.VKerning)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeBlock(w io.Writer, blockType byte, data []byte) error {
+	if _, err := w.Write([]byte{blockType}); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, int32(len(data))); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+func encodeInfoBinary(i *Info) []byte {
+	var bitField byte
+	if i.Smooth {
+		bitField |= 0x01
+	}
+	if i.Unicode {
+		bitField |= 0x02
+	}
+	if i.Italic {
+		bitField |= 0x04
+	}
+	if i.Bold {
+		bitField |= 0x08
+	}
+	var charSet byte
+	if len(i.Charset) > 0 {
+		charSet = i.Charset[0]
+	}
+	buf := make([]byte, 14, 14+len(i.Face)+1)
+	binary.LittleEndian.PutUint16(buf[0:2], uint16(int16(i.Size)))
+	buf[2] = bitField
+	buf[3] = charSet
+	binary.LittleEndian.PutUint16(buf[4:6], uint16(i.StretchH))
+	buf[6] = byte(i.AA)
+	buf[7] = byte(i.Padding.Up)
+	buf[8] = byte(i.Padding.Right)
+	buf[9] = byte(i.Padding.Down)
+	buf[10] = byte(i.Padding.Left)
+	buf[11] = byte(i.Spacing.Horizontal)
+	buf[12] = byte(i.Spacing.Vertical)
+	buf[13] = byte(i.Outline)
+	buf = append(buf, i.Face...)
+	buf = append(buf, 0)
+	return buf
+}
+
+func encodeCommonBinary(c *Common, pages int) []byte {
+	var bitField byte
+	if c.Packed {
+		bitField |= 0x01
+	}
+	buf := make([]byte, commonSizeBinary)
+	binary.LittleEndian.PutUint16(buf[0:2], uint16(c.LineHeight))
+	binary.LittleEndian.PutUint16(buf[2:4], uint16(c.Base))
+	binary.LittleEndian.PutUint16(buf[4:6], uint16(c.ScaleW))
+	binary.LittleEndian.PutUint16(buf[6:8], uint16(c.ScaleH))
+	binary.LittleEndian.PutUint16(buf[8:10], uint16(pages))
+	buf[10] = bitField
+	buf[11] = byte(c.AlphaChannel)
+	buf[12] = byte(c.RedChannel)
+	buf[13] = byte(c.GreenChannel)
+	buf[14] = byte(c.BlueChannel)
+	return buf
+}
+
+func encodePagesBinary(pages map[int]Page) []byte {
+	var buf bytes.Buffer
+	for _, id := range sortedPageIDs(pages) {
+		buf.WriteString(pages[id].File)
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
+
+func encodeCharsBinary(chars map[rune]Char) []byte {
+	buf := make([]byte, 0, len(chars)*charSizeBinary)
+	for _, id := range sortedCharIDs(chars) {
+		c := chars[id]
+		var b [charSizeBinary]byte
+		binary.LittleEndian.PutUint32(b[0:4], uint32(c.ID))
+		binary.LittleEndian.PutUint16(b[4:6], uint16(c.X))
+		binary.LittleEndian.PutUint16(b[6:8], uint16(c.Y))
+		binary.LittleEndian.PutUint16(b[8:10], uint16(c.Width))
+		binary.LittleEndian.PutUint16(b[10:12], uint16(c.Height))
+		binary.LittleEndian.PutUint16(b[12:14], uint16(int16(c.XOffset)))
+		binary.LittleEndian.PutUint16(b[14:16], uint16(int16(c.YOffset)))
+		binary.LittleEndian.PutUint16(b[16:18], uint16(int16(c.XAdvance)))
+		b[18] = byte(c.Page)
+		b[19] = byte(c.Channel)
+		buf = append(buf, b[:]...)
+	}
+	return buf
+}
+
+func encodeKerningBinary(kerning map[CharPair]Kerning) []byte {
+	pairs := sortedCharPairs(kerning)
+	buf := make([]byte, 0, len(pairs)*kerningSizeBinary)
+	for _, p := range pairs {
+		var b [kerningSizeBinary]byte
+		binary.LittleEndian.PutUint32(b[0:4], uint32(p.First))
+		binary.LittleEndian.PutUint32(b[4:8], uint32(p.Second))
+		binary.LittleEndian.PutUint16(b[8:10], uint16(int16(kerning[p].Amount)))
+		buf = append(buf, b[:]...)
+	}
+	return buf
+}
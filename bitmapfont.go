@@ -0,0 +1,91 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmrot
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/bmp"
+)
+
+// A BitmapFont is a complete BMFont bitmap font: its descriptor together
+// with the page sheet images it references, keyed by page ID.
+type BitmapFont struct {
+	Descriptor *Descriptor
+	Pages      map[int]image.Image
+}
+
+// Load loads a bitmap font from a BMFont descriptor file together with all
+// of its referenced page sheet images. The page images are expected to live
+// alongside the descriptor file, as named by each Page.File. Both PNG and
+// BMP page images are supported. If you only need the descriptor, use
+// LoadDescriptor instead.
+func Load(path string) (*BitmapFont, error) {
+	d, err := LoadDescriptor(path)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(path)
+	pages := make(map[int]image.Image, len(d.Pages))
+	for id, page := range d.Pages {
+		img, err := loadPageImage(filepath.Join(dir, page.File))
+		if err != nil {
+			return nil, fmt.Errorf("page %d (%s): %w", id, page.File, err)
+		}
+		pages[id] = img
+	}
+	return &BitmapFont{Descriptor: d, Pages: pages}, nil
+}
+
+func loadPageImage(path string) (img image.Image, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closeChecked(f, &err)
+	if strings.EqualFold(filepath.Ext(path), ".bmp") {
+		return bmp.Decode(f)
+	}
+	return png.Decode(f)
+}
+
+// SavePageImage writes img to path, encoding as BMP if path has a ".bmp"
+// extension and as PNG otherwise.
+func SavePageImage(path string, img image.Image) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer closeChecked(f, &err)
+	if strings.EqualFold(filepath.Ext(path), ".bmp") {
+		return bmp.Encode(f, img)
+	}
+	return png.Encode(f, img)
+}
+
+// Transform reorients the font according to op: both the descriptor
+// metadata (via Descriptor.Transform) and the page sheet images. It
+// returns the transformed page images keyed by page ID; the BitmapFont's
+// own Pages are left untouched so the caller can decide how and where to
+// save the result.
+func (bf *BitmapFont) Transform(op Orientation) map[int]image.Image {
+	transformed := make(map[int]image.Image, len(bf.Pages))
+	for id, img := range bf.Pages {
+		transformed[id] = transformImage(op, img)
+	}
+	bf.Descriptor.Transform(op)
+	return transformed
+}
+
+// Rotate rotates the font 90 degrees clockwise. It is a thin wrapper
+// around Transform(Rotate90CW), kept for backwards compatibility.
+func (bf *BitmapFont) Rotate() map[int]image.Image {
+	return bf.Transform(Rotate90CW)
+}
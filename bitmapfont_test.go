@@ -0,0 +1,82 @@
+package bmrot
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// testImage builds a 3x2 RGBA image where each pixel's red channel encodes
+// its (x, y) coordinate, so a transform's pixel movement can be checked
+// exactly.
+func testImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			img.Set(x, y, color.RGBA{R: byte(x), G: byte(y), B: 0, A: 255})
+		}
+	}
+	return img
+}
+
+func at(img image.Image, x, y int) (r, g int) {
+	c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+	return int(c.R), int(c.G)
+}
+
+// TestBitmapFontRotate checks that Rotate (90 degrees clockwise) moves
+// pixels to where a 90-degree-clockwise rotation should put them: the
+// source's top-left corner ends up at the destination's top-right.
+func TestBitmapFontRotate(t *testing.T) {
+	bf := &BitmapFont{
+		Descriptor: &Descriptor{Common: Common{ScaleW: 3, ScaleH: 2}, Chars: map[rune]Char{}},
+		Pages:      map[int]image.Image{0: testImage()},
+	}
+	rotated := bf.Rotate()
+	dst := rotated[0]
+
+	b := dst.Bounds()
+	if b.Dx() != 2 || b.Dy() != 3 {
+		t.Fatalf("rotated bounds = %v, want 2x3", b)
+	}
+	// Source (0,0) -> destination top-right (Dx-1, 0).
+	if r, g := at(dst, 1, 0); r != 0 || g != 0 {
+		t.Errorf("dst(1,0) = (%d,%d), want (0,0)", r, g)
+	}
+	// Source (2,0) (top-right) -> destination bottom-right (Dx-1, Dy-1).
+	if r, g := at(dst, 1, 2); r != 2 || g != 0 {
+		t.Errorf("dst(1,2) = (%d,%d), want (2,0)", r, g)
+	}
+	// Source (0,1) (bottom-left) -> destination top-left (0,0).
+	if r, g := at(dst, 0, 0); r != 0 || g != 1 {
+		t.Errorf("dst(0,0) = (%d,%d), want (0,1)", r, g)
+	}
+}
+
+// TestBitmapFontTransformRoundTrip checks that rotating an image 90 degrees
+// clockwise and then 90 degrees counter-clockwise (270 CW) restores every
+// pixel.
+func TestBitmapFontTransformRoundTrip(t *testing.T) {
+	src := testImage()
+	bf := &BitmapFont{
+		Descriptor: &Descriptor{Common: Common{ScaleW: 3, ScaleH: 2}, Chars: map[rune]Char{}},
+		Pages:      map[int]image.Image{0: src},
+	}
+	once := bf.Transform(Rotate90CW)
+	bf.Pages = once
+	back := bf.Transform(Rotate270CW)
+	dst := back[0]
+
+	b := dst.Bounds()
+	if b.Dx() != 3 || b.Dy() != 2 {
+		t.Fatalf("round-tripped bounds = %v, want 3x2", b)
+	}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			wantR, wantG := x, y
+			if r, g := at(dst, x, y); r != wantR || g != wantG {
+				t.Errorf("dst(%d,%d) = (%d,%d), want (%d,%d)", x, y, r, g, wantR, wantG)
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package bmrot
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+)
+
+// BuildOptions controls how BuildFromSFNT and BuildFromBDF rasterize
+// glyphs and lay them out into a generated BMFont atlas.
+type BuildOptions struct {
+	// PixelSize is the em size, in pixels, to rasterize an SFNT font at.
+	// It is ignored by BuildFromBDF, which uses the bitmap sizes already
+	// baked into the BDF file.
+	PixelSize float64
+	// Runes is the set of characters to include in the atlas. If empty, it
+	// defaults to printable ASCII (0x20-0x7e).
+	Runes []rune
+	// Padding adds blank pixels around each glyph's ink within its cell.
+	Padding Padding
+	// Spacing adds blank pixels between adjacent glyph cells.
+	Spacing Spacing
+	// AtlasSize is the maximum size of a single page. If either dimension
+	// is zero, it defaults to 512x512; glyphs that don't fit on one page
+	// spill onto additional pages.
+	AtlasSize image.Point
+	// Channel selects which image channel(s) glyph coverage is written
+	// into and is recorded on every Char. The zero value defaults to All
+	// (opaque white glyphs, with coverage carried in the alpha channel).
+	Channel Channel
+}
+
+func (o BuildOptions) runes() []rune {
+	if len(o.Runes) > 0 {
+		return o.Runes
+	}
+	runes := make([]rune, 0, 0x7f-0x20)
+	for r := rune(0x20); r <= 0x7e; r++ {
+		runes = append(runes, r)
+	}
+	return runes
+}
+
+func (o BuildOptions) atlasSize() image.Point {
+	if o.AtlasSize.X > 0 && o.AtlasSize.Y > 0 {
+		return o.AtlasSize
+	}
+	return image.Pt(512, 512)
+}
+
+func (o BuildOptions) channel() Channel {
+	if o.Channel == 0 {
+		return All
+	}
+	return o.Channel
+}
+
+// glyphBitmap is a single rasterized glyph, ready to be packed into an
+// atlas page and recorded as a Char. draw paints the glyph's coverage into
+// dst with its top-left corner at (x, y); it is nil for zero-size glyphs
+// (e.g. space), which are recorded with no ink but keep their xadvance.
+type glyphBitmap struct {
+	r                rune
+	width, height    int
+	xoffset, yoffset int
+	xadvance         int
+	draw             func(dst draw.Image, x, y int)
+}
+
+// buildAtlas bins glyphs into one or more ScaleW x ScaleH pages (per
+// opts.AtlasSize) using a skyline packer, drawing each onto its page and
+// recording its placement as a Char. lineHeight and base become the
+// descriptor's Common.LineHeight and Common.Base. Glyphs whose cell doesn't
+// fit even on a fresh, empty page are omitted from the atlas and returned
+// in dropped, so the caller can decide whether that's acceptable.
+func buildAtlas(glyphs []glyphBitmap, lineHeight, base int, opts BuildOptions) (bf *BitmapFont, dropped []rune) {
+	size := opts.atlasSize()
+	chnl := opts.channel()
+	pad, sp := opts.Padding, opts.Spacing
+
+	var packers []*skylinePacker
+	pages := map[int]image.Image{}
+	ensurePage := func(id int) {
+		if _, ok := pages[id]; !ok {
+			pages[id] = image.NewRGBA(image.Rect(0, 0, size.X, size.Y))
+		}
+	}
+
+	chars := make(map[rune]Char, len(glyphs))
+	for _, g := range glyphs {
+		if g.width <= 0 || g.height <= 0 {
+			ensurePage(0)
+			chars[g.r] = Char{ID: g.r, XOffset: g.xoffset, YOffset: g.yoffset, XAdvance: g.xadvance, Channel: chnl}
+			continue
+		}
+
+		cellW := g.width + pad.Left + pad.Right + sp.Horizontal
+		cellH := g.height + pad.Up + pad.Down + sp.Vertical
+
+		page, x, y, ok := -1, 0, 0, false
+		for i, p := range packers {
+			if x, y, ok = p.insert(cellW, cellH); ok {
+				page = i
+				break
+			}
+		}
+		if !ok {
+			p := newSkylinePacker(size.X, size.Y)
+			if x, y, ok = p.insert(cellW, cellH); !ok {
+				dropped = append(dropped, g.r)
+				continue
+			}
+			packers = append(packers, p)
+			page = len(packers) - 1
+			ensurePage(page)
+		}
+
+		gx, gy := x+pad.Left, y+pad.Up
+		if g.draw != nil {
+			g.draw(pages[page].(draw.Image), gx, gy)
+		}
+		chars[g.r] = Char{
+			ID:       g.r,
+			X:        gx,
+			Y:        gy,
+			Width:    g.width,
+			Height:   g.height,
+			XOffset:  g.xoffset,
+			YOffset:  g.yoffset,
+			XAdvance: g.xadvance,
+			Page:     page,
+			Channel:  chnl,
+		}
+	}
+
+	pageMeta := make(map[int]Page, len(pages))
+	for id := range pages {
+		pageMeta[id] = Page{ID: id, File: fmt.Sprintf("page%d.png", id)}
+	}
+
+	bf = &BitmapFont{
+		Descriptor: &Descriptor{
+			Info: Info{
+				Padding: pad,
+				Spacing: sp,
+			},
+			Common: Common{
+				LineHeight: lineHeight,
+				Base:       base,
+				ScaleW:     size.X,
+				ScaleH:     size.Y,
+			},
+			Pages:   pageMeta,
+			Chars:   chars,
+			Kerning: make(map[CharPair]Kerning),
+		},
+		Pages: pages,
+	}
+	return bf, dropped
+}
+
+// errDroppedGlyphs reports runes that buildAtlas could not place on any
+// page, e.g. because a single glyph's cell is larger than opts.AtlasSize.
+func errDroppedGlyphs(dropped []rune) error {
+	return fmt.Errorf("bmrot: %d glyph(s) didn't fit in the atlas and were dropped: %q", len(dropped), string(dropped))
+}
@@ -0,0 +1,122 @@
+package bmrot
+
+import (
+	"image"
+	"image/draw"
+	"strings"
+	"testing"
+)
+
+// glyph builds a glyphBitmap of the given size with no-op drawing, for
+// exercising buildAtlas's packing logic in isolation.
+func glyph(r rune, w, h int) glyphBitmap {
+	return glyphBitmap{r: r, width: w, height: h, draw: func(dst draw.Image, x, y int) {}}
+}
+
+// TestBuildAtlasPacksOntoOnePage checks that glyphs small enough to share a
+// page are placed side by side on page 0, with padding/spacing reflected in
+// their cell positions.
+func TestBuildAtlasPacksOntoOnePage(t *testing.T) {
+	opts := BuildOptions{AtlasSize: image.Pt(64, 64)}
+	glyphs := []glyphBitmap{glyph('A', 10, 10), glyph('B', 10, 10)}
+
+	bf, dropped := buildAtlas(glyphs, 12, 10, opts)
+	if len(dropped) != 0 {
+		t.Fatalf("dropped = %v, want none", dropped)
+	}
+	if len(bf.Pages) != 1 {
+		t.Fatalf("len(Pages) = %d, want 1", len(bf.Pages))
+	}
+
+	a, b := bf.Descriptor.Chars['A'], bf.Descriptor.Chars['B']
+	if a.Page != 0 || b.Page != 0 {
+		t.Errorf("A.Page=%d B.Page=%d, want both 0", a.Page, b.Page)
+	}
+	if a.X == b.X && a.Y == b.Y {
+		t.Errorf("A and B were placed at the same cell: %+v / %+v", a, b)
+	}
+}
+
+// TestBuildAtlasSpillsOntoNewPage checks that a glyph that doesn't fit
+// alongside earlier ones, but does fit on its own, spills onto a new page
+// rather than being dropped.
+func TestBuildAtlasSpillsOntoNewPage(t *testing.T) {
+	opts := BuildOptions{AtlasSize: image.Pt(16, 16)}
+	glyphs := []glyphBitmap{glyph('A', 16, 16), glyph('B', 16, 16)}
+
+	bf, dropped := buildAtlas(glyphs, 16, 16, opts)
+	if len(dropped) != 0 {
+		t.Fatalf("dropped = %v, want none", dropped)
+	}
+	if len(bf.Pages) != 2 {
+		t.Fatalf("len(Pages) = %d, want 2", len(bf.Pages))
+	}
+	a, b := bf.Descriptor.Chars['A'], bf.Descriptor.Chars['B']
+	if a.Page == b.Page {
+		t.Errorf("A.Page=%d B.Page=%d, want different pages", a.Page, b.Page)
+	}
+}
+
+// TestBuildAtlasDropsOversizedGlyph checks that a glyph too big for even an
+// empty page is reported as dropped instead of silently discarded.
+func TestBuildAtlasDropsOversizedGlyph(t *testing.T) {
+	opts := BuildOptions{AtlasSize: image.Pt(8, 8)}
+	glyphs := []glyphBitmap{glyph('A', 16, 16)}
+
+	_, dropped := buildAtlas(glyphs, 16, 16, opts)
+	if len(dropped) != 1 || dropped[0] != 'A' {
+		t.Fatalf("dropped = %v, want [A]", dropped)
+	}
+}
+
+// bdfSample is a minimal one-glyph BDF font: a fully-filled 8x8 bitmap for
+// 'A', with explicit FONT_ASCENT/FONT_DESCENT.
+const bdfSample = `STARTFONT 2.1
+FONT -test-test-Medium-R-Normal--8-80-75-75-P-50-ISO10646-1
+SIZE 8 75 75
+FONTBOUNDINGBOX 8 8 0 0
+FONT_ASCENT 7
+FONT_DESCENT 1
+CHARS 1
+STARTCHAR A
+ENCODING 65
+DWIDTH 8 0
+BBX 8 8 0 0
+BITMAP
+FF
+FF
+FF
+FF
+FF
+FF
+FF
+FF
+ENDCHAR
+ENDFONT
+`
+
+// TestBuildFromBDFPlacesGlyph checks that BuildFromBDF bakes the BDF's
+// bitmap glyph into the atlas with the file's own metrics, rather than
+// dropping or mis-sizing it.
+func TestBuildFromBDFPlacesGlyph(t *testing.T) {
+	bf, err := BuildFromBDF(strings.NewReader(bdfSample), BuildOptions{Runes: []rune{'A'}})
+	if err != nil {
+		t.Fatalf("BuildFromBDF: %v", err)
+	}
+	c, ok := bf.Descriptor.Chars['A']
+	if !ok {
+		t.Fatal("Chars['A'] missing")
+	}
+	if c.Width != 8 || c.Height != 8 {
+		t.Errorf("A size = %dx%d, want 8x8", c.Width, c.Height)
+	}
+	if c.XAdvance != 8 {
+		t.Errorf("A.XAdvance = %d, want 8", c.XAdvance)
+	}
+	if bf.Descriptor.Common.LineHeight != 8 {
+		t.Errorf("LineHeight = %d, want 8 (ascent+descent)", bf.Descriptor.Common.LineHeight)
+	}
+	if _, ok := bf.Pages[c.Page]; !ok {
+		t.Errorf("page %d referenced by char A is missing from Pages", c.Page)
+	}
+}
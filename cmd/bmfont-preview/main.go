@@ -0,0 +1,196 @@
+// Command bmfont-preview serves an HTML page for interactively inspecting
+// one or more BMFont bitmap fonts: it lists the fonts given on the command
+// line and renders a sample string using each one's glyph metrics and
+// kerning, with a query parameter to preview Descriptor.Rotate() before
+// saving a rotated font to disk.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"image"
+	"image/draw"
+	"image/png"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dyuri/bmrot"
+)
+
+// fontEntry holds both orientations of a loaded font, computed once at
+// startup so a request can toggle rotation without mutating shared state.
+type fontEntry struct {
+	name           string
+	plain, rotated *bmrot.BitmapFont
+}
+
+var fonts = map[string]*fontEntry{}
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-addr addr] <font.fnt>...\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	for _, path := range flag.Args() {
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		plain, err := bmrot.Load(path)
+		if err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+		rotated, err := bmrot.Load(path)
+		if err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+		rotated.Pages = rotated.Rotate()
+		fonts[name] = &fontEntry{name: name, plain: plain, rotated: rotated}
+		log.Printf("loaded %s from %s", name, path)
+	}
+
+	http.HandleFunc("/", handleIndex)
+	http.HandleFunc("/render", handleRender)
+	log.Printf("listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head><title>bmfont-preview</title></head>
+<body>
+<h1>bmfont-preview</h1>
+<form action="/render" method="get">
+<select name="font">
+{{range .}}<option value="{{.}}">{{.}}</option>
+{{end}}</select>
+<input type="text" name="text" value="The quick brown fox">
+<label><input type="checkbox" name="rotate" value="1"> rotated</label>
+<button type="submit">render</button>
+</form>
+{{range .}}<p>{{.}}: <img src="/render?font={{.}}&text=Sample"></p>
+{{end}}
+</body>
+</html>
+`))
+
+func handleIndex(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(fonts))
+	for name := range fonts {
+		names = append(names, name)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := indexTemplate.Execute(w, names); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func handleRender(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("font")
+	entry, ok := fonts[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	text := r.URL.Query().Get("text")
+	if text == "" {
+		text = "Sample"
+	}
+	bf := entry.plain
+	if r.URL.Query().Get("rotate") != "" {
+		bf = entry.rotated
+	}
+
+	img := renderText(bf, text)
+	w.Header().Set("Content-Type", "image/png")
+	if err := png.Encode(w, img); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// renderText composites text onto a transparent canvas using bf's glyph
+// metrics and kerning. Lines are separated by '\n'. Characters missing
+// from bf.Descriptor.Chars are skipped.
+func renderText(bf *bmrot.BitmapFont, text string) image.Image {
+	d := bf.Descriptor
+	lines := strings.Split(text, "\n")
+
+	width := 0
+	for _, line := range lines {
+		if w := lineWidth(d, line); w > width {
+			width = w
+		}
+	}
+	height := d.Common.LineHeight * len(lines)
+	if width <= 0 {
+		width = 1
+	}
+	if height <= 0 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i, line := range lines {
+		drawLine(dst, bf, line, i*d.Common.LineHeight)
+	}
+	return dst
+}
+
+// lineWidth measures the pixel width line would occupy when drawn with d's
+// glyph advances and kerning.
+func lineWidth(d *bmrot.Descriptor, line string) int {
+	x := 0
+	var prev rune
+	for i, r := range line {
+		c, ok := d.Chars[r]
+		if !ok {
+			continue
+		}
+		if i > 0 {
+			if k, ok := d.Kerning[bmrot.CharPair{First: prev, Second: r}]; ok {
+				x += k.Amount
+			}
+		}
+		x += c.XAdvance
+		prev = r
+	}
+	return x
+}
+
+// drawLine draws one line of text into dst at vertical offset top, using
+// bf's page images, glyph metrics and kerning.
+func drawLine(dst draw.Image, bf *bmrot.BitmapFont, line string, top int) {
+	d := bf.Descriptor
+	x := 0
+	var prev rune
+	first := true
+	for _, r := range line {
+		c, ok := d.Chars[r]
+		if !ok {
+			continue
+		}
+		if !first {
+			if k, ok := d.Kerning[bmrot.CharPair{First: prev, Second: r}]; ok {
+				x += k.Amount
+			}
+		}
+		first = false
+
+		page := bf.Pages[c.Page]
+		if page != nil && c.Width > 0 && c.Height > 0 {
+			dp := image.Pt(x+c.XOffset, top+c.YOffset)
+			target := image.Rectangle{Min: dp, Max: dp.Add(c.Size())}
+			draw.Draw(dst, target, page, c.Pos(), draw.Over)
+		}
+		x += c.XAdvance
+		prev = r
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dyuri/bmrot"
+)
+
+// testFont builds a tiny two-glyph BitmapFont ('A' and 'B', each a solid
+// 4x4 cell on a single page) with a kerning pair between them, for
+// exercising lineWidth/renderText/drawLine without needing a real font
+// file on disk.
+func testFont() *bmrot.BitmapFont {
+	page := image.NewRGBA(image.Rect(0, 0, 16, 4))
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			page.Set(x, y, color.RGBA{R: 255, A: 255})
+			page.Set(x+4, y, color.RGBA{G: 255, A: 255})
+		}
+	}
+	d := &bmrot.Descriptor{
+		Common: bmrot.Common{LineHeight: 4},
+		Chars: map[rune]bmrot.Char{
+			'A': {ID: 'A', X: 0, Y: 0, Width: 4, Height: 4, XAdvance: 5},
+			'B': {ID: 'B', X: 4, Y: 0, Width: 4, Height: 4, XAdvance: 5},
+		},
+		Kerning: map[bmrot.CharPair]bmrot.Kerning{
+			{First: 'A', Second: 'B'}: {Amount: -2},
+		},
+	}
+	return &bmrot.BitmapFont{Descriptor: d, Pages: map[int]image.Image{0: page}}
+}
+
+func TestLineWidth(t *testing.T) {
+	bf := testFont()
+	// "A" alone: just its XAdvance.
+	if w := lineWidth(bf.Descriptor, "A"); w != 5 {
+		t.Errorf(`lineWidth("A") = %d, want 5`, w)
+	}
+	// "AB": A's XAdvance, then the A-B kerning, then B's XAdvance.
+	if w := lineWidth(bf.Descriptor, "AB"); w != 5-2+5 {
+		t.Errorf(`lineWidth("AB") = %d, want %d`, w, 5-2+5)
+	}
+	// A char missing from the font is skipped entirely.
+	if w := lineWidth(bf.Descriptor, "AxB"); w != 5-2+5 {
+		t.Errorf(`lineWidth("AxB") = %d, want %d (missing char skipped)`, w, 5-2+5)
+	}
+}
+
+func TestRenderText(t *testing.T) {
+	bf := testFont()
+	img := renderText(bf, "AB")
+
+	wantWidth := lineWidth(bf.Descriptor, "AB")
+	b := img.Bounds()
+	if b.Dx() != wantWidth || b.Dy() != bf.Descriptor.Common.LineHeight {
+		t.Fatalf("renderText bounds = %v, want %dx%d", b, wantWidth, bf.Descriptor.Common.LineHeight)
+	}
+
+	// 'A' is drawn at the origin; its red pixel should show through.
+	if r, _, _, a := img.At(0, 0).RGBA(); r == 0 || a == 0 {
+		t.Errorf("pixel (0,0) = r=%d a=%d, want A's opaque red", r, a)
+	}
+	// 'B' starts at x = A.XAdvance + kerning = 5 - 2 = 3, so its green
+	// pixel should show there.
+	if _, g, _, a := img.At(3, 0).RGBA(); g == 0 || a == 0 {
+		t.Errorf("pixel (3,0) = g=%d a=%d, want B's opaque green", g, a)
+	}
+}
+
+func TestRenderTextMultiline(t *testing.T) {
+	bf := testFont()
+	img := renderText(bf, "A\nB")
+	b := img.Bounds()
+	if wantH := 2 * bf.Descriptor.Common.LineHeight; b.Dy() != wantH {
+		t.Errorf("renderText bounds.Dy() = %d, want %d (two lines)", b.Dy(), wantH)
+	}
+}
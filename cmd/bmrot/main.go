@@ -0,0 +1,85 @@
+// Command bmrot reorients a BMFont bitmap font (descriptor and page sheet
+// images): by default it rotates 90 degrees clockwise, but any orientation
+// can be selected with -orientation.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dyuri/bmrot"
+)
+
+func printDescriptor(desc *bmrot.Descriptor) {
+	fmt.Printf("%s\n", desc)
+}
+
+func main() {
+	formatFlag := flag.String("f", "text", "output descriptor format: text, xml or binary")
+	orientationFlag := flag.String("orientation", "90", "orientation transform: 0, 90, 180, 270, fliph, flipv, transpose, transverse (or EXIF 1-8)")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-f format] [-orientation orientation] <filename>\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	format, err := bmrot.ParseFormat(*formatFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	orientation, err := bmrot.ParseOrientation(*orientationFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	filename := flag.Arg(0)
+	bf, err := bmrot.Load(filename)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	transformedPages := bf.Transform(orientation)
+	if err := saveRotated(filename, bf.Descriptor, transformedPages, format); err != nil {
+		log.Fatal(err)
+	}
+
+	printDescriptor(bf.Descriptor)
+}
+
+// saveRotated writes the rotated page images and a new descriptor file next
+// to srcPath, both named with a "_rotated" suffix, and updates d.Pages to
+// point at the new image file names. The descriptor is written in format.
+func saveRotated(srcPath string, d *bmrot.Descriptor, pages map[int]image.Image, format bmrot.Format) error {
+	dir := filepath.Dir(srcPath)
+	for id, img := range pages {
+		page := d.Pages[id]
+		outFile := rotatedName(page.File)
+		if err := bmrot.SavePageImage(filepath.Join(dir, outFile), img); err != nil {
+			return fmt.Errorf("page %d (%s): %w", id, page.File, err)
+		}
+		d.Pages[id] = bmrot.Page{ID: page.ID, File: outFile}
+	}
+
+	outFnt := filepath.Join(dir, rotatedName(filepath.Base(srcPath)))
+	f, err := os.Create(outFnt)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return d.WriteDescriptor(f, format)
+}
+
+// rotatedName inserts a "_rotated" suffix before name's extension.
+func rotatedName(name string) string {
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "_rotated" + ext
+}
@@ -2,14 +2,17 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-package main
+package bmrot
 
 import (
+	"bufio"
 	"fmt"
 	"image"
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 )
 
 // A Descriptor holds metadata for a bitmap font.
@@ -19,6 +22,9 @@ type Descriptor struct {
 	Pages   map[int]Page
 	Chars   map[rune]Char
 	Kerning map[CharPair]Kerning
+	// VKerning holds vertical kerning adjustments, used in place of Kerning
+	// once a font has been rotated for vertical text layout. See Rotate.
+	VKerning map[CharPair]Kerning
 }
 
 func (d *Descriptor) String() string {
@@ -30,7 +36,21 @@ func (d *Descriptor) String() string {
 	for _, char := range d.Chars {
 		chars += fmt.Sprintf("%s\n", char.String())
 	}
-	return fmt.Sprintf("%s\n%s\n%s%s", d.Info.String(), printCommon(&d.Common, &d.Pages), pages, chars)
+	kernings := ""
+	if len(d.Kerning) > 0 {
+		kernings = fmt.Sprintf("kernings count=%d\n", len(d.Kerning))
+		for pair, k := range d.Kerning {
+			kernings += fmt.Sprintf("kerning first=%d second=%d amount=%d\n", pair.First, pair.Second, k.Amount)
+		}
+	}
+	vkernings := ""
+	if len(d.VKerning) > 0 {
+		vkernings = fmt.Sprintf("vkernings count=%d\n", len(d.VKerning))
+		for pair, k := range d.VKerning {
+			vkernings += fmt.Sprintf("vkerning first=%d second=%d amount=%d\n", pair.First, pair.Second, k.Amount)
+		}
+	}
+	return fmt.Sprintf("%s\n%s\n%s%s%s%s", d.Info.String(), printCommon(&d.Common, &d.Pages), pages, chars, kernings, vkernings)
 }
 
 func printCommon(c *Common, p *map[int]Page) string {
@@ -48,24 +68,64 @@ func printCommon(c *Common, p *map[int]Page) string {
 	)
 }
 
-// Rotate rotates the font (descriptor) 90 degrees clockwise.
-func (d *Descriptor) Rotate() {
-	d.Info.Padding = Padding{d.Info.Padding.Left, d.Info.Padding.Up, d.Info.Padding.Right, d.Info.Padding.Down}
-	d.Info.Spacing = Spacing{d.Info.Spacing.Vertical, d.Info.Spacing.Horizontal}
-	d.Common.ScaleW, d.Common.ScaleH = d.Common.ScaleH, d.Common.ScaleW
+// Transform reorients the font (descriptor) according to op: Info.Padding
+// and Info.Spacing are permuted to match, Common.ScaleW/ScaleH are swapped
+// for the four 90-degree orientations, and every Char's geometry is
+// remapped onto the transformed page.
+//
+// Width and Height only change places with XAdvance and LineHeight/Base
+// once op turns the glyphs onto their side (Transpose, Rotate90CW,
+// Transverse, Rotate270CW): for those orientations XAdvance is recomputed
+// from the new Width/XOffset, and LineHeight/Base from the tallest
+// resulting glyph. Rotate0, FlipH, FlipV and Rotate180 leave glyph extents
+// untouched, so XAdvance and LineHeight/Base are left as they were.
+//
+// Horizontal kerning no longer makes sense once op turns the glyphs onto
+// their side for vertical text, so for those same orientations Kerning and
+// VKerning swap places: Kerning becomes the vertical stacking adjustments
+// and VKerning the (now meaningless) horizontal ones. Swapping, rather than
+// always moving Kerning into VKerning, is what makes repeated Transform
+// calls on the same Descriptor compose correctly: two Rotate90CW calls in a
+// row swap twice and land back on the original Kerning, matching the net
+// 180-degree (non-swapping) orientation they amount to.
+func (d *Descriptor) Transform(op Orientation) {
+	oldW, oldH := d.Common.ScaleW, d.Common.ScaleH
+
+	sides := paddingSides(op)
+	old := [4]int{d.Info.Padding.Up, d.Info.Padding.Right, d.Info.Padding.Down, d.Info.Padding.Left}
+	d.Info.Padding = Padding{Up: old[sides[sideUp]], Right: old[sides[sideRight]], Down: old[sides[sideDown]], Left: old[sides[sideLeft]]}
+
+	if op.swapsDims() {
+		d.Info.Spacing = Spacing{Horizontal: d.Info.Spacing.Vertical, Vertical: d.Info.Spacing.Horizontal}
+		d.Common.ScaleW, d.Common.ScaleH = oldH, oldW
+	}
+
 	lh := 0
 	for _, char := range d.Chars {
-		char.X, char.Y = d.Common.ScaleW-char.Y-char.Height, char.X
-		char.XOffset, char.YOffset = char.YOffset, char.XOffset
-		char.Width, char.Height = char.Height, char.Width
-		char.XAdvance = char.Width + char.XOffset
+		char.X, char.Y, char.Width, char.Height = transformBox(op, char.X, char.Y, char.Width, char.Height, oldW, oldH)
+		if op.swapsDims() {
+			char.XOffset, char.YOffset = char.YOffset, char.XOffset
+			char.XAdvance = char.Width + char.XOffset
+		}
 		if lh < char.Height {
 			lh = char.Height
 		}
 		d.Chars[char.ID] = char
 	}
-	d.Common.LineHeight = lh
-	d.Common.Base = lh
+	if op.swapsDims() {
+		d.Common.LineHeight = lh
+		d.Common.Base = lh
+	}
+
+	if op.swapsDims() {
+		d.Kerning, d.VKerning = d.VKerning, d.Kerning
+	}
+}
+
+// Rotate rotates the font (descriptor) 90 degrees clockwise. It is a thin
+// wrapper around Transform(Rotate90CW), kept for backwards compatibility.
+func (d *Descriptor) Rotate() {
+	d.Transform(Rotate90CW)
 }
 
 type Info struct {
@@ -231,23 +291,209 @@ func closeChecked(c io.Closer, err *error) {
 	}
 }
 
-// LoadDescriptor loads the font descriptor data from a BMFont descriptor file in
-// text format (usually with the file extension .fnt). It does not load the
-// referenced page sheet images. If you also want to load the page sheet
-// images, use the Load function to get a complete BitmapFont instance.
+// LoadDescriptor loads the font descriptor data from a BMFont descriptor
+// file (usually with the file extension .fnt), autodetecting whether it is
+// in text, XML or binary format. It does not load the referenced page
+// sheet images. If you also want to load the page sheet images, use the
+// Load function to get a complete BitmapFont instance.
 func LoadDescriptor(path string) (d *Descriptor, err error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer closeChecked(f, &err)
-	return parseDescriptor(filepath.Base(path), f)
+	return readDescriptor(filepath.Base(path), f)
 }
 
-// ReadDescriptor parses font descriptor data in BMFont's text format from a
-// reader. It does not load the referenced page sheet images. If you also want
-// to load the page sheet images, use the Load function to get a complete
-// BitmapFont instance.
+// ReadDescriptor parses font descriptor data from a reader, autodetecting
+// whether it is in text, XML or binary format. It does not load the
+// referenced page sheet images. If you also want to load the page sheet
+// images, use the Load function to get a complete BitmapFont instance.
 func ReadDescriptor(r io.Reader) (d *Descriptor, err error) {
-	return parseDescriptor("bmfont", r)
+	return readDescriptor("bmfont", r)
+}
+
+// parseDescriptor parses font descriptor data in BMFont's text format. The
+// name is only used in error messages.
+func parseDescriptor(name string, r io.Reader) (*Descriptor, error) {
+	d := &Descriptor{
+		Pages:   make(map[int]Page),
+		Chars:   make(map[rune]Char),
+		Kerning: make(map[CharPair]Kerning),
+	}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		tag, fields := parseLine(scanner.Text())
+		if tag == "" {
+			continue
+		}
+		switch tag {
+		case "info":
+			d.Info = parseInfo(fields)
+		case "common":
+			d.Common = parseCommon(fields)
+		case "page":
+			p := Page{
+				ID:   atoi(fields["id"]),
+				File: fields["file"],
+			}
+			d.Pages[p.ID] = p
+		case "char":
+			c := parseChar(fields)
+			d.Chars[c.ID] = c
+		case "kerning":
+			d.Kerning[CharPair{
+				First:  rune(atoi(fields["first"])),
+				Second: rune(atoi(fields["second"])),
+			}] = Kerning{Amount: atoi(fields["amount"])}
+		case "vkerning":
+			if d.VKerning == nil {
+				d.VKerning = make(map[CharPair]Kerning)
+			}
+			d.VKerning[CharPair{
+				First:  rune(atoi(fields["first"])),
+				Second: rune(atoi(fields["second"])),
+			}] = Kerning{Amount: atoi(fields["amount"])}
+		case "chars", "kernings", "vkernings":
+			// Count lines only announce how many char/kerning/vkerning
+			// lines follow; the entries themselves are parsed as they occur.
+		default:
+			return nil, fmt.Errorf("%s:%d: unknown tag %q", name, line, tag)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return d, nil
+}
+
+func parseInfo(f map[string]string) Info {
+	return Info{
+		Face:     f["face"],
+		Size:     atoi(f["size"]),
+		Bold:     atob(f["bold"]),
+		Italic:   atob(f["italic"]),
+		Charset:  f["charset"],
+		Unicode:  atob(f["unicode"]),
+		StretchH: atoi(f["stretchH"]),
+		Smooth:   atob(f["smooth"]),
+		AA:       atoi(f["aa"]),
+		Padding:  parsePadding(f["padding"]),
+		Spacing:  parseSpacing(f["spacing"]),
+		Outline:  atoi(f["outline"]),
+	}
+}
+
+func parsePadding(s string) Padding {
+	v := splitInts(s)
+	if len(v) != 4 {
+		return Padding{}
+	}
+	return Padding{Up: v[0], Right: v[1], Down: v[2], Left: v[3]}
+}
+
+func parseSpacing(s string) Spacing {
+	v := splitInts(s)
+	if len(v) != 2 {
+		return Spacing{}
+	}
+	return Spacing{Horizontal: v[0], Vertical: v[1]}
+}
+
+func parseCommon(f map[string]string) Common {
+	return Common{
+		LineHeight:   atoi(f["lineHeight"]),
+		Base:         atoi(f["base"]),
+		ScaleW:       atoi(f["scaleW"]),
+		ScaleH:       atoi(f["scaleH"]),
+		Packed:       atob(f["packed"]),
+		AlphaChannel: ChannelInfo(atoi(f["alphaChnl"])),
+		RedChannel:   ChannelInfo(atoi(f["redChnl"])),
+		GreenChannel: ChannelInfo(atoi(f["greenChnl"])),
+		BlueChannel:  ChannelInfo(atoi(f["blueChnl"])),
+	}
+}
+
+func parseChar(f map[string]string) Char {
+	return Char{
+		ID:       rune(atoi(f["id"])),
+		X:        atoi(f["x"]),
+		Y:        atoi(f["y"]),
+		Width:    atoi(f["width"]),
+		Height:   atoi(f["height"]),
+		XOffset:  atoi(f["xoffset"]),
+		YOffset:  atoi(f["yoffset"]),
+		XAdvance: atoi(f["xadvance"]),
+		Page:     atoi(f["page"]),
+		Channel:  Channel(atoi(f["chnl"])),
+	}
+}
+
+// parseLine splits a BMFont descriptor line into its tag (the first word,
+// e.g. "info" or "char") and its key=value fields. Values may be quoted to
+// contain spaces.
+func parseLine(s string) (tag string, fields map[string]string) {
+	fields = make(map[string]string)
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", fields
+	}
+	sp := strings.IndexAny(s, " \t")
+	if sp < 0 {
+		return s, fields
+	}
+	tag, rest := s[:sp], s[sp+1:]
+	for _, tok := range tokenize(rest) {
+		if eq := strings.IndexByte(tok, '='); eq >= 0 {
+			fields[tok[:eq]] = strings.Trim(tok[eq+1:], `"`)
+		}
+	}
+	return tag, fields
+}
+
+// tokenize splits a string on whitespace, keeping double-quoted substrings
+// (which may themselves contain spaces) intact as single tokens.
+func tokenize(s string) []string {
+	var tokens []string
+	var b strings.Builder
+	inQuotes := false
+	flush := func() {
+		if b.Len() > 0 {
+			tokens = append(tokens, b.String())
+			b.Reset()
+		}
+	}
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			b.WriteRune(r)
+		case (r == ' ' || r == '\t') && !inQuotes:
+			flush()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+func splitInts(s string) []int {
+	parts := strings.Split(s, ",")
+	v := make([]int, len(parts))
+	for i, p := range parts {
+		v[i] = atoi(p)
+	}
+	return v
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(s))
+	return n
+}
+
+func atob(s string) bool {
+	return atoi(s) != 0
 }
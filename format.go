@@ -0,0 +1,140 @@
+package bmrot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Format identifies one of the on-disk encodings of a BMFont descriptor.
+type Format int
+
+const (
+	FormatText Format = iota
+	FormatXML
+	FormatBinary
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatXML:
+		return "xml"
+	case FormatBinary:
+		return "binary"
+	default:
+		return "text"
+	}
+}
+
+// ParseFormat parses a format name ("text", "xml" or "binary", as used by
+// the bmrot -f flag) into a Format.
+func ParseFormat(s string) (Format, error) {
+	switch s {
+	case "", "text":
+		return FormatText, nil
+	case "xml":
+		return FormatXML, nil
+	case "binary":
+		return FormatBinary, nil
+	default:
+		return 0, fmt.Errorf("unknown format %q (want text, xml or binary)", s)
+	}
+}
+
+// binaryMagic is the 4-byte header that identifies a BMFont binary
+// descriptor: the letters "BMF" followed by the format version (3).
+var binaryMagic = [4]byte{'B', 'M', 'F', 3}
+
+// detectFormat peeks at the start of r to determine which descriptor format
+// it holds. It does not consume any bytes, so the caller can go on to parse
+// r from the beginning.
+func detectFormat(r *bufio.Reader) (Format, error) {
+	head, err := r.Peek(4)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	if len(head) == 4 && [4]byte{head[0], head[1], head[2], head[3]} == binaryMagic {
+		return FormatBinary, nil
+	}
+	for _, b := range head {
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '<':
+			return FormatXML, nil
+		}
+		break
+	}
+	return FormatText, nil
+}
+
+// readDescriptor autodetects the format of r and parses it accordingly. name
+// is only used in error messages.
+func readDescriptor(name string, r io.Reader) (*Descriptor, error) {
+	br := bufio.NewReader(r)
+	format, err := detectFormat(br)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	switch format {
+	case FormatBinary:
+		return parseBinaryDescriptor(name, br)
+	case FormatXML:
+		return parseXMLDescriptor(name, br)
+	default:
+		return parseDescriptor(name, br)
+	}
+}
+
+// WriteDescriptor writes d to w in the given format.
+func (d *Descriptor) WriteDescriptor(w io.Writer, format Format) error {
+	switch format {
+	case FormatBinary:
+		return d.writeBinary(w)
+	case FormatXML:
+		return d.writeXML(w)
+	default:
+		_, err := io.WriteString(w, d.String())
+		return err
+	}
+}
+
+// sortedPageIDs returns the keys of pages in ascending order, so formats
+// that encode pages positionally (binary) or just want stable output (XML)
+// can rely on it.
+func sortedPageIDs(pages map[int]Page) []int {
+	ids := make([]int, 0, len(pages))
+	for id := range pages {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// sortedCharIDs returns the keys of chars in ascending order, for stable
+// output.
+func sortedCharIDs(chars map[rune]Char) []rune {
+	ids := make([]rune, 0, len(chars))
+	for id := range chars {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// sortedCharPairs returns the keys of kerning in ascending (First, Second)
+// order, for stable output.
+func sortedCharPairs(kerning map[CharPair]Kerning) []CharPair {
+	pairs := make([]CharPair, 0, len(kerning))
+	for p := range kerning {
+		pairs = append(pairs, p)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].First != pairs[j].First {
+			return pairs[i].First < pairs[j].First
+		}
+		return pairs[i].Second < pairs[j].Second
+	})
+	return pairs
+}
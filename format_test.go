@@ -0,0 +1,95 @@
+package bmrot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func sampleDescriptorForRoundTrip() *Descriptor {
+	return &Descriptor{
+		Info: Info{
+			Face: "Arial", Size: 32, Bold: true, Italic: false, Charset: "A",
+			Unicode: true, StretchH: 100, Smooth: true, AA: 1,
+			Padding: Padding{Up: 1, Right: 2, Down: 3, Left: 4},
+			Spacing: Spacing{Horizontal: 5, Vertical: 6},
+			Outline: 0,
+		},
+		Common: Common{
+			LineHeight: 32, Base: 26, ScaleW: 256, ScaleH: 256,
+			AlphaChannel: Glyph, RedChannel: Glyph, GreenChannel: Glyph, BlueChannel: Glyph,
+		},
+		Pages: map[int]Page{0: {ID: 0, File: "page0.png"}},
+		Chars: map[rune]Char{
+			'A': {ID: 'A', X: 0, Y: 0, Width: 20, Height: 30, XAdvance: 22, Page: 0, Channel: All},
+			'B': {ID: 'B', X: 20, Y: 0, Width: 18, Height: 30, XOffset: 1, YOffset: 2, XAdvance: 20, Page: 0, Channel: All},
+		},
+		Kerning:  map[CharPair]Kerning{{First: 'A', Second: 'B'}: {Amount: -2}},
+		VKerning: map[CharPair]Kerning{{First: 'A', Second: 'B'}: {Amount: 3}},
+	}
+}
+
+// TestDescriptorRoundTrip writes a descriptor in each binary format and
+// reads it back via the format-autodetecting readDescriptor, checking that
+// every field survives the round trip.
+func TestDescriptorRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatBinary, FormatXML} {
+		t.Run(format.String(), func(t *testing.T) {
+			want := sampleDescriptorForRoundTrip()
+			var buf bytes.Buffer
+			if err := want.WriteDescriptor(&buf, format); err != nil {
+				t.Fatalf("WriteDescriptor: %v", err)
+			}
+			got, err := readDescriptor("test", &buf)
+			if err != nil {
+				t.Fatalf("readDescriptor: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip mismatch:\n got = %+v\nwant = %+v", got, want)
+			}
+		})
+	}
+}
+
+// TestParseBinaryDescriptorRejectsOversizedBlock checks that a corrupted
+// block-size prefix is rejected before the corresponding allocation, rather
+// than forcing a huge make([]byte, size).
+func TestParseBinaryDescriptorRejectsOversizedBlock(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(binaryMagic[:])
+	buf.WriteByte(blockInfo)
+	binary.Write(&buf, binary.LittleEndian, int32(1<<30))
+
+	_, err := parseBinaryDescriptor("test", &buf)
+	if err == nil {
+		t.Fatal("parseBinaryDescriptor: got nil error for oversized block, want error")
+	}
+}
+
+// TestParseBinaryDescriptorRejectsTruncatedBlock checks that an info or
+// common block shorter than its fixed layout returns an error instead of
+// panicking while indexing into it.
+func TestParseBinaryDescriptorRejectsTruncatedBlock(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		blockType byte
+		data      []byte
+	}{
+		{"info", blockInfo, []byte{0xAA, 0xBB}},
+		{"common", blockCommon, []byte{0xAA, 0xBB, 0xCC}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			buf.Write(binaryMagic[:])
+			buf.WriteByte(tc.blockType)
+			binary.Write(&buf, binary.LittleEndian, int32(len(tc.data)))
+			buf.Write(tc.data)
+
+			_, err := parseBinaryDescriptor("test", &buf)
+			if err == nil {
+				t.Fatalf("parseBinaryDescriptor: got nil error for truncated %s block, want error", tc.name)
+			}
+		})
+	}
+}
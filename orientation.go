@@ -0,0 +1,195 @@
+package bmrot
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"strings"
+)
+
+// Orientation identifies one of the eight ways a page image (and its
+// descriptor metadata) can be reoriented: the four quarter turns, a
+// horizontal or vertical flip, and the two diagonal flip+rotate
+// compositions. The values and their numbering mirror the EXIF
+// "Orientation" tag (1-8).
+type Orientation int
+
+const (
+	Rotate0     Orientation = iota + 1 // EXIF 1: no change
+	FlipH                              // EXIF 2: mirrored left-right
+	Rotate180                          // EXIF 3: rotated 180 degrees
+	FlipV                              // EXIF 4: mirrored top-bottom
+	Transpose                          // EXIF 5: mirrored left-right, then rotated 90 CW
+	Rotate90CW                         // EXIF 6: rotated 90 degrees clockwise
+	Transverse                         // EXIF 7: mirrored left-right, then rotated 270 CW
+	Rotate270CW                        // EXIF 8: rotated 270 degrees clockwise (90 CCW)
+)
+
+func (op Orientation) String() string {
+	switch op {
+	case Rotate0:
+		return "Rotate0"
+	case FlipH:
+		return "FlipH"
+	case Rotate180:
+		return "Rotate180"
+	case FlipV:
+		return "FlipV"
+	case Transpose:
+		return "Transpose"
+	case Rotate90CW:
+		return "Rotate90CW"
+	case Transverse:
+		return "Transverse"
+	case Rotate270CW:
+		return "Rotate270CW"
+	default:
+		return fmt.Sprintf("Orientation(%d)", int(op))
+	}
+}
+
+// ParseOrientation parses an Orientation from its name (case-insensitive,
+// e.g. "rotate90cw" or "fliph"), a bare rotation angle ("0", "90", "180",
+// "270"), or its EXIF orientation number ("1" through "8").
+func ParseOrientation(s string) (Orientation, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "0", "rotate0", "none":
+		return Rotate0, nil
+	case "90", "rotate90cw", "rotate90":
+		return Rotate90CW, nil
+	case "180", "rotate180":
+		return Rotate180, nil
+	case "270", "rotate270cw", "rotate270":
+		return Rotate270CW, nil
+	case "fliph", "flip-h", "hflip":
+		return FlipH, nil
+	case "flipv", "flip-v", "vflip":
+		return FlipV, nil
+	case "transpose":
+		return Transpose, nil
+	case "transverse":
+		return Transverse, nil
+	case "1":
+		return Rotate0, nil
+	case "2":
+		return FlipH, nil
+	case "3":
+		return Rotate180, nil
+	case "4":
+		return FlipV, nil
+	case "5":
+		return Transpose, nil
+	case "6":
+		return Rotate90CW, nil
+	case "7":
+		return Transverse, nil
+	case "8":
+		return Rotate270CW, nil
+	default:
+		return 0, fmt.Errorf("unknown orientation %q (want 0, 90, 180, 270, fliph, flipv, transpose, transverse, or EXIF 1-8)", s)
+	}
+}
+
+// swapsDims reports whether op swaps width and height: the four
+// orientations that involve a 90-degree turn.
+func (op Orientation) swapsDims() bool {
+	switch op {
+	case Transpose, Rotate90CW, Transverse, Rotate270CW:
+		return true
+	default:
+		return false
+	}
+}
+
+// Indices into the four-element (Up, Right, Down, Left) side arrays used
+// by paddingSides and Descriptor.Transform.
+const (
+	sideUp = iota
+	sideRight
+	sideDown
+	sideLeft
+)
+
+// paddingSides returns, for each new side (Up, Right, Down, Left, in that
+// order), the index of the old side it takes its value from, once op has
+// been applied.
+func paddingSides(op Orientation) [4]int {
+	switch op {
+	case FlipH:
+		return [4]int{sideUp, sideLeft, sideDown, sideRight}
+	case Rotate180:
+		return [4]int{sideDown, sideLeft, sideUp, sideRight}
+	case FlipV:
+		return [4]int{sideDown, sideRight, sideUp, sideLeft}
+	case Transpose:
+		return [4]int{sideLeft, sideDown, sideRight, sideUp}
+	case Rotate90CW:
+		return [4]int{sideLeft, sideUp, sideRight, sideDown}
+	case Transverse:
+		return [4]int{sideRight, sideUp, sideLeft, sideDown}
+	case Rotate270CW:
+		return [4]int{sideRight, sideDown, sideLeft, sideUp}
+	default: // Rotate0
+		return [4]int{sideUp, sideRight, sideDown, sideLeft}
+	}
+}
+
+// transformBox maps a char's box (x, y, w, h) from an oldW x oldH page to
+// its position after op is applied.
+func transformBox(op Orientation, x, y, w, h, oldW, oldH int) (nx, ny, nw, nh int) {
+	switch op {
+	case FlipH:
+		return oldW - x - w, y, w, h
+	case Rotate180:
+		return oldW - x - w, oldH - y - h, w, h
+	case FlipV:
+		return x, oldH - y - h, w, h
+	case Transpose:
+		return y, x, h, w
+	case Rotate90CW:
+		return oldH - y - h, x, h, w
+	case Transverse:
+		return oldH - y - h, oldW - x - w, h, w
+	case Rotate270CW:
+		return y, oldW - x - w, h, w
+	default: // Rotate0
+		return x, y, w, h
+	}
+}
+
+// transformImage returns a copy of src with op applied.
+func transformImage(op Orientation, src image.Image) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	size := image.Rect(0, 0, w, h)
+	if op.swapsDims() {
+		size = image.Rect(0, 0, h, w)
+	}
+	dst := image.NewRGBA(size)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			xr, yr := x-b.Min.X, y-b.Min.Y
+			var dx, dy int
+			switch op {
+			case FlipH:
+				dx, dy = w-1-xr, yr
+			case Rotate180:
+				dx, dy = w-1-xr, h-1-yr
+			case FlipV:
+				dx, dy = xr, h-1-yr
+			case Transpose:
+				dx, dy = yr, xr
+			case Rotate90CW:
+				dx, dy = h-1-yr, xr
+			case Transverse:
+				dx, dy = h-1-yr, w-1-xr
+			case Rotate270CW:
+				dx, dy = yr, w-1-xr
+			default: // Rotate0
+				dx, dy = xr, yr
+			}
+			draw.Draw(dst, image.Rect(dx, dy, dx+1, dy+1), src, image.Pt(x, y), draw.Src)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,96 @@
+package bmrot
+
+import "testing"
+
+// TestDescriptorTransformPreservesNonRotatingMetrics checks the bug
+// reported in review: Rotate0, FlipH, FlipV and Rotate180 must not touch
+// XAdvance or LineHeight/Base, since they don't change glyph extents.
+func TestDescriptorTransformPreservesNonRotatingMetrics(t *testing.T) {
+	for _, op := range []Orientation{Rotate0, FlipH, FlipV, Rotate180} {
+		d := &Descriptor{
+			Common: Common{ScaleW: 100, ScaleH: 50, LineHeight: 20, Base: 16},
+			Chars:  map[rune]Char{' ': {ID: ' ', Width: 0, XOffset: 0, XAdvance: 8}},
+		}
+		d.Transform(op)
+		if got := d.Chars[' ']; got.XAdvance != 8 {
+			t.Errorf("%s: XAdvance = %d, want 8 (unchanged)", op, got.XAdvance)
+		}
+		if d.Common.LineHeight != 20 {
+			t.Errorf("%s: LineHeight = %d, want 20 (unchanged)", op, d.Common.LineHeight)
+		}
+		if d.Common.Base != 16 {
+			t.Errorf("%s: Base = %d, want 16 (unchanged)", op, d.Common.Base)
+		}
+	}
+}
+
+// TestDescriptorTransformRotatingMetrics checks that the four
+// 90-degree-class orientations do recompute XAdvance and LineHeight/Base
+// from the transformed geometry.
+func TestDescriptorTransformRotatingMetrics(t *testing.T) {
+	for _, op := range []Orientation{Transpose, Rotate90CW, Transverse, Rotate270CW} {
+		d := &Descriptor{
+			Common: Common{ScaleW: 100, ScaleH: 50, LineHeight: 20, Base: 16},
+			Chars:  map[rune]Char{'A': {ID: 'A', X: 10, Y: 20, Width: 8, Height: 12, XOffset: 1}},
+		}
+		d.Transform(op)
+		c := d.Chars['A']
+		if want := c.Width + c.XOffset; c.XAdvance != want {
+			t.Errorf("%s: XAdvance = %d, want %d (Width+XOffset)", op, c.XAdvance, want)
+		}
+		if d.Common.LineHeight != c.Height {
+			t.Errorf("%s: LineHeight = %d, want %d (tallest glyph)", op, d.Common.LineHeight, c.Height)
+		}
+	}
+}
+
+// TestDescriptorTransformRoundTrip checks that applying an orientation and
+// then its inverse restores the original char geometry.
+func TestDescriptorTransformRoundTrip(t *testing.T) {
+	inverse := map[Orientation]Orientation{
+		Rotate0:     Rotate0,
+		FlipH:       FlipH,
+		FlipV:       FlipV,
+		Rotate180:   Rotate180,
+		Rotate90CW:  Rotate270CW,
+		Rotate270CW: Rotate90CW,
+		Transpose:   Transpose,
+		Transverse:  Transverse,
+	}
+	for op, inv := range inverse {
+		d := &Descriptor{
+			Common: Common{ScaleW: 100, ScaleH: 50},
+			Chars:  map[rune]Char{'A': {ID: 'A', X: 10, Y: 20, Width: 8, Height: 12}},
+		}
+		want := d.Chars['A']
+		d.Transform(op)
+		d.Transform(inv)
+		got := d.Chars['A']
+		if got.X != want.X || got.Y != want.Y || got.Width != want.Width || got.Height != want.Height {
+			t.Errorf("%s then %s: char = %+v, want %+v", op, inv, got, want)
+		}
+		if d.Common.ScaleW != 100 || d.Common.ScaleH != 50 {
+			t.Errorf("%s then %s: scale = %dx%d, want 100x50", op, inv, d.Common.ScaleW, d.Common.ScaleH)
+		}
+	}
+}
+
+// TestDescriptorTransformRepeatedKerningRoundTrip checks the bug reported
+// in review: calling Transform(Rotate90CW) twice nets a 180-degree
+// (non-swapping) orientation, so Kerning must end up back in Kerning, not
+// stuck in VKerning.
+func TestDescriptorTransformRepeatedKerningRoundTrip(t *testing.T) {
+	d := &Descriptor{
+		Common:  Common{ScaleW: 100, ScaleH: 50},
+		Kerning: map[CharPair]Kerning{{First: 'A', Second: 'B'}: {Amount: -2}},
+	}
+	d.Transform(Rotate90CW)
+	d.Transform(Rotate90CW)
+
+	if len(d.VKerning) != 0 {
+		t.Errorf("after two Rotate90CW calls: VKerning = %v, want empty", d.VKerning)
+	}
+	if got := d.Kerning[CharPair{First: 'A', Second: 'B'}]; got.Amount != -2 {
+		t.Errorf("after two Rotate90CW calls: Kerning[A,B].Amount = %d, want -2", got.Amount)
+	}
+}
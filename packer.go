@@ -0,0 +1,43 @@
+package bmrot
+
+// skylinePacker packs axis-aligned rectangles into a fixed-width strip
+// using the skyline heuristic: it tracks the current height profile across
+// the strip's width and places each new rectangle at the lowest position
+// that fits, preferring the leftmost such position.
+type skylinePacker struct {
+	width, height int
+	skyline       []int // skyline[x] is the lowest free y at column x
+}
+
+func newSkylinePacker(width, height int) *skylinePacker {
+	return &skylinePacker{width: width, height: height, skyline: make([]int, width)}
+}
+
+// insert finds room for a w x h rectangle and returns its top-left corner.
+// ok is false if the rectangle does not fit within the packer's bounds.
+func (p *skylinePacker) insert(w, h int) (x, y int, ok bool) {
+	if w <= 0 || h <= 0 || w > p.width {
+		return 0, 0, false
+	}
+	bestY := p.height
+	bestX := -1
+	for x := 0; x+w <= p.width; x++ {
+		y := p.skyline[x]
+		for i := x + 1; i < x+w; i++ {
+			if p.skyline[i] > y {
+				y = p.skyline[i]
+			}
+		}
+		if y < bestY {
+			bestY = y
+			bestX = x
+		}
+	}
+	if bestX < 0 || bestY+h > p.height {
+		return 0, 0, false
+	}
+	for i := bestX; i < bestX+w; i++ {
+		p.skyline[i] = bestY + h
+	}
+	return bestX, bestY, true
+}
@@ -0,0 +1,46 @@
+package bmrot
+
+import "testing"
+
+func TestSkylinePackerInsert(t *testing.T) {
+	p := newSkylinePacker(10, 10)
+
+	x, y, ok := p.insert(4, 3)
+	if !ok || x != 0 || y != 0 {
+		t.Fatalf("insert(4,3) = (%d,%d,%v), want (0,0,true)", x, y, ok)
+	}
+
+	// Packed next to the first rectangle, same row.
+	x, y, ok = p.insert(4, 3)
+	if !ok || x != 4 || y != 0 {
+		t.Fatalf("insert(4,3) = (%d,%d,%v), want (4,0,true)", x, y, ok)
+	}
+
+	// Doesn't fit in the remaining width of the first row, so it goes below.
+	x, y, ok = p.insert(4, 2)
+	if !ok || x != 0 || y != 3 {
+		t.Fatalf("insert(4,2) = (%d,%d,%v), want (0,3,true)", x, y, ok)
+	}
+}
+
+func TestSkylinePackerInsertRejectsOversizedRect(t *testing.T) {
+	p := newSkylinePacker(10, 10)
+
+	if _, _, ok := p.insert(11, 1); ok {
+		t.Error("insert(11,1) into a width-10 packer: got ok=true, want false")
+	}
+	if _, _, ok := p.insert(0, 1); ok {
+		t.Error("insert(0,1): got ok=true, want false")
+	}
+
+	// Fill the packer, then check a rectangle that no longer fits height-wise
+	// is rejected.
+	for i := 0; i < 10; i++ {
+		if _, _, ok := p.insert(10, 1); !ok {
+			t.Fatalf("insert(10,1) #%d: got ok=false, want true", i)
+		}
+	}
+	if _, _, ok := p.insert(1, 1); ok {
+		t.Error("insert(1,1) into a full packer: got ok=true, want false")
+	}
+}
@@ -0,0 +1,72 @@
+package bmrot
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// BuildFromSFNT rasterizes f at opts.PixelSize with font.Drawer's glyph
+// rasterizer and bakes the requested runes (opts.Runes, or printable ASCII
+// by default) into a BMFont atlas, including kerning pairs read from f's
+// kern table.
+func BuildFromSFNT(f *sfnt.Font, opts BuildOptions) (*BitmapFont, error) {
+	size := opts.PixelSize
+	if size <= 0 {
+		size = 16
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	metrics := face.Metrics()
+	ascent := metrics.Ascent.Ceil()
+
+	runes := opts.runes()
+	white := image.NewUniform(color.White)
+	glyphs := make([]glyphBitmap, 0, len(runes))
+	for _, r := range runes {
+		dr, mask, maskp, advance, ok := face.Glyph(fixed.P(0, 0), r)
+		if !ok {
+			continue
+		}
+		glyphs = append(glyphs, glyphBitmap{
+			r:        r,
+			width:    dr.Dx(),
+			height:   dr.Dy(),
+			xoffset:  dr.Min.X,
+			yoffset:  ascent + dr.Min.Y,
+			xadvance: advance.Ceil(),
+			draw: func(dst draw.Image, x, y int) {
+				target := image.Rect(x, y, x+dr.Dx(), y+dr.Dy())
+				draw.DrawMask(dst, target, white, image.Point{}, mask, maskp, draw.Over)
+			},
+		})
+	}
+
+	bf, dropped := buildAtlas(glyphs, metrics.Height.Ceil(), ascent, opts)
+	if len(dropped) > 0 {
+		return nil, errDroppedGlyphs(dropped)
+	}
+	bf.Descriptor.Info.Size = int(size)
+
+	for _, r0 := range runes {
+		for _, r1 := range runes {
+			if amt := face.Kern(r0, r1); amt != 0 {
+				bf.Descriptor.Kerning[CharPair{First: r0, Second: r1}] = Kerning{Amount: amt.Round()}
+			}
+		}
+	}
+	return bf, nil
+}
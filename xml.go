@@ -0,0 +1,240 @@
+package bmrot
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// xmlFont mirrors the standard BMFont XML descriptor layout, as emitted by
+// the AngelCode tool and most engines that read it.
+type xmlFont struct {
+	XMLName  xml.Name     `xml:"font"`
+	Info     xmlInfo      `xml:"info"`
+	Common   xmlCommon    `xml:"common"`
+	Pages    xmlPages     `xml:"pages"`
+	Chars    xmlChars     `xml:"chars"`
+	Kernings *xmlKernings `xml:"kernings,omitempty"`
+	// VKernings is this package's own extension, holding Descriptor.VKerning
+	// (see Descriptor.Rotate). It is not part of the standard BMFont XML
+	// schema.
+	VKernings *xmlKernings `xml:"vkernings,omitempty"`
+}
+
+type xmlInfo struct {
+	Face     string `xml:"face,attr"`
+	Size     int    `xml:"size,attr"`
+	Bold     int    `xml:"bold,attr"`
+	Italic   int    `xml:"italic,attr"`
+	Charset  string `xml:"charset,attr"`
+	Unicode  int    `xml:"unicode,attr"`
+	StretchH int    `xml:"stretchH,attr"`
+	Smooth   int    `xml:"smooth,attr"`
+	AA       int    `xml:"aa,attr"`
+	Padding  string `xml:"padding,attr"`
+	Spacing  string `xml:"spacing,attr"`
+	Outline  int    `xml:"outline,attr"`
+}
+
+type xmlCommon struct {
+	LineHeight int `xml:"lineHeight,attr"`
+	Base       int `xml:"base,attr"`
+	ScaleW     int `xml:"scaleW,attr"`
+	ScaleH     int `xml:"scaleH,attr"`
+	Pages      int `xml:"pages,attr"`
+	Packed     int `xml:"packed,attr"`
+	AlphaChnl  int `xml:"alphaChnl,attr"`
+	RedChnl    int `xml:"redChnl,attr"`
+	GreenChnl  int `xml:"greenChnl,attr"`
+	BlueChnl   int `xml:"blueChnl,attr"`
+}
+
+type xmlPages struct {
+	Page []xmlPage `xml:"page"`
+}
+
+type xmlPage struct {
+	ID   int    `xml:"id,attr"`
+	File string `xml:"file,attr"`
+}
+
+type xmlChars struct {
+	Count int       `xml:"count,attr"`
+	Char  []xmlChar `xml:"char"`
+}
+
+type xmlChar struct {
+	ID       int `xml:"id,attr"`
+	X        int `xml:"x,attr"`
+	Y        int `xml:"y,attr"`
+	Width    int `xml:"width,attr"`
+	Height   int `xml:"height,attr"`
+	XOffset  int `xml:"xoffset,attr"`
+	YOffset  int `xml:"yoffset,attr"`
+	XAdvance int `xml:"xadvance,attr"`
+	Page     int `xml:"page,attr"`
+	Channel  int `xml:"chnl,attr"`
+}
+
+type xmlKernings struct {
+	Count   int          `xml:"count,attr"`
+	Kerning []xmlKerning `xml:"kerning"`
+}
+
+type xmlKerning struct {
+	First  int `xml:"first,attr"`
+	Second int `xml:"second,attr"`
+	Amount int `xml:"amount,attr"`
+}
+
+// parseXMLDescriptor parses a BMFont descriptor in the XML format. name is
+// only used in error messages.
+func parseXMLDescriptor(name string, r io.Reader) (*Descriptor, error) {
+	var x xmlFont
+	if err := xml.NewDecoder(r).Decode(&x); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	d := &Descriptor{
+		Pages:   make(map[int]Page),
+		Chars:   make(map[rune]Char),
+		Kerning: make(map[CharPair]Kerning),
+	}
+	d.Info = Info{
+		Face:     x.Info.Face,
+		Size:     x.Info.Size,
+		Bold:     x.Info.Bold != 0,
+		Italic:   x.Info.Italic != 0,
+		Charset:  x.Info.Charset,
+		Unicode:  x.Info.Unicode != 0,
+		StretchH: x.Info.StretchH,
+		Smooth:   x.Info.Smooth != 0,
+		AA:       x.Info.AA,
+		Padding:  parsePadding(x.Info.Padding),
+		Spacing:  parseSpacing(x.Info.Spacing),
+		Outline:  x.Info.Outline,
+	}
+	d.Common = Common{
+		LineHeight:   x.Common.LineHeight,
+		Base:         x.Common.Base,
+		ScaleW:       x.Common.ScaleW,
+		ScaleH:       x.Common.ScaleH,
+		Packed:       x.Common.Packed != 0,
+		AlphaChannel: ChannelInfo(x.Common.AlphaChnl),
+		RedChannel:   ChannelInfo(x.Common.RedChnl),
+		GreenChannel: ChannelInfo(x.Common.GreenChnl),
+		BlueChannel:  ChannelInfo(x.Common.BlueChnl),
+	}
+	for _, p := range x.Pages.Page {
+		d.Pages[p.ID] = Page{ID: p.ID, File: p.File}
+	}
+	for _, c := range x.Chars.Char {
+		ch := Char{
+			ID:       rune(c.ID),
+			X:        c.X,
+			Y:        c.Y,
+			Width:    c.Width,
+			Height:   c.Height,
+			XOffset:  c.XOffset,
+			YOffset:  c.YOffset,
+			XAdvance: c.XAdvance,
+			Page:     c.Page,
+			Channel:  Channel(c.Channel),
+		}
+		d.Chars[ch.ID] = ch
+	}
+	if x.Kernings != nil {
+		for _, k := range x.Kernings.Kerning {
+			d.Kerning[CharPair{First: rune(k.First), Second: rune(k.Second)}] = Kerning{Amount: k.Amount}
+		}
+	}
+	if x.VKernings != nil {
+		d.VKerning = make(map[CharPair]Kerning, len(x.VKernings.Kerning))
+		for _, k := range x.VKernings.Kerning {
+			d.VKerning[CharPair{First: rune(k.First), Second: rune(k.Second)}] = Kerning{Amount: k.Amount}
+		}
+	}
+	return d, nil
+}
+
+// writeXML encodes d in the BMFont XML format (see parseXMLDescriptor).
+func (d *Descriptor) writeXML(w io.Writer) error {
+	x := xmlFont{
+		Info: xmlInfo{
+			Face:     d.Info.Face,
+			Size:     d.Info.Size,
+			Bold:     boolToInt(d.Info.Bold),
+			Italic:   boolToInt(d.Info.Italic),
+			Charset:  d.Info.Charset,
+			Unicode:  boolToInt(d.Info.Unicode),
+			StretchH: d.Info.StretchH,
+			Smooth:   boolToInt(d.Info.Smooth),
+			AA:       d.Info.AA,
+			Padding:  fmt.Sprintf("%d,%d,%d,%d", d.Info.Padding.Up, d.Info.Padding.Right, d.Info.Padding.Down, d.Info.Padding.Left),
+			Spacing:  fmt.Sprintf("%d,%d", d.Info.Spacing.Horizontal, d.Info.Spacing.Vertical),
+			Outline:  d.Info.Outline,
+		},
+		Common: xmlCommon{
+			LineHeight: d.Common.LineHeight,
+			Base:       d.Common.Base,
+			ScaleW:     d.Common.ScaleW,
+			ScaleH:     d.Common.ScaleH,
+			Pages:      len(d.Pages),
+			Packed:     boolToInt(d.Common.Packed),
+			AlphaChnl:  int(d.Common.AlphaChannel),
+			RedChnl:    int(d.Common.RedChannel),
+			GreenChnl:  int(d.Common.GreenChannel),
+			BlueChnl:   int(d.Common.BlueChannel),
+		},
+	}
+	for _, id := range sortedPageIDs(d.Pages) {
+		p := d.Pages[id]
+		x.Pages.Page = append(x.Pages.Page, xmlPage{ID: p.ID, File: p.File})
+	}
+	x.Chars.Count = len(d.Chars)
+	for _, id := range sortedCharIDs(d.Chars) {
+		c := d.Chars[id]
+		x.Chars.Char = append(x.Chars.Char, xmlChar{
+			ID:       int(c.ID),
+			X:        c.X,
+			Y:        c.Y,
+			Width:    c.Width,
+			Height:   c.Height,
+			XOffset:  c.XOffset,
+			YOffset:  c.YOffset,
+			XAdvance: c.XAdvance,
+			Page:     c.Page,
+			Channel:  int(c.Channel),
+		})
+	}
+	if len(d.Kerning) > 0 {
+		x.Kernings = &xmlKernings{Count: len(d.Kerning)}
+		for _, p := range sortedCharPairs(d.Kerning) {
+			x.Kernings.Kerning = append(x.Kernings.Kerning, xmlKerning{
+				First:  int(p.First),
+				Second: int(p.Second),
+				Amount: d.Kerning[p].Amount,
+			})
+		}
+	}
+	if len(d.VKerning) > 0 {
+		x.VKernings = &xmlKernings{Count: len(d.VKerning)}
+		for _, p := range sortedCharPairs(d.VKerning) {
+			x.VKernings.Kerning = append(x.VKernings.Kerning, xmlKerning{
+				First:  int(p.First),
+				Second: int(p.Second),
+				Amount: d.VKerning[p].Amount,
+			})
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(&x); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}